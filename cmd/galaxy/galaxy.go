@@ -29,12 +29,14 @@ Please consider project repository to read more documentation:
 // parameters by using Viper.
 func configFromEnv() *galaxy.Config {
 	return &galaxy.Config{
-		DotGalaxyPath: viper.GetString("config"),
-		DryRun:        viper.GetBool("dry-run"),
-		Environments:  viper.GetString("environment"),
-		Namespaces:    viper.GetString("namespace"),
-		LogLevel:      viper.GetString("log-level"),
-		SkipSecrets:   viper.GetBool("skip-secrets"),
+		DotGalaxyPath:  viper.GetString("config"),
+		DryRun:         viper.GetBool("dry-run"),
+		Environments:   viper.GetString("environment"),
+		Namespaces:     viper.GetString("namespace"),
+		LogLevel:       viper.GetString("log-level"),
+		SkipSecrets:    viper.GetBool("skip-secrets"),
+		SkipHistory:    viper.GetBool("skip-history"),
+		MaxConcurrency: viper.GetInt("max-concurrency"),
 		KubernetesConfig: &galaxy.KubernetesConfig{
 			InCluster:   viper.GetBool("in-cluster"),
 			KubeConfig:  viper.GetString("kube-config"),
@@ -59,14 +61,32 @@ func configFromEnv() *galaxy.Config {
 	}
 }
 
-// bootstrap reads the configuration from command-line informed place, and set log-level
+// bootstrap discovers the workspace rooted at cfg.DotGalaxyPath (merging any `.galaxy.d/*.yaml`
+// overlay found alongside it), and resolves any `{{ vault:... }}` /
+// `{{ secret:... }}` references found on the resulting manifest.
 func bootstrap(cfg *galaxy.Config) *galaxy.DotGalaxy {
-	var dotGalaxy *galaxy.DotGalaxy
-	var err error
+	workspace, err := galaxy.DiscoverWorkspace(cfg.DotGalaxyPath)
+	if err != nil {
+		log.Fatalf("[ERROR] Discovering workspace ('%s'): %s", cfg.DotGalaxyPath, err)
+	}
+
+	for _, ep := range workspace.Environments() {
+		log.Debugf("Environment '%s' sourced from '%s'", ep.Environment.Name, ep.Source)
+	}
+	for _, np := range workspace.Namespaces() {
+		log.Debugf("Namespace '%s' sourced from '%s'", np.Name, np.Source)
+	}
+
+	dotGalaxy := workspace.DotGalaxy
 
-	if dotGalaxy, err = galaxy.NewDotGalaxy(cfg.DotGalaxyPath); err != nil {
-		log.Fatalf("[ERROR] Parsing dot-galaxy file ('%s'): %s", cfg.DotGalaxyPath, err)
+	resolver, err := galaxy.ResolveDotGalaxy(dotGalaxy, cfg.VaultHandlerConfig, cfg.KubernetesConfig, cfg.DryRun)
+	if err != nil {
+		log.Fatalf("[ERROR] Resolving secret references: %s", err)
 	}
+	for _, ref := range resolver.Unresolved() {
+		log.Warnf("[DRY-RUN] Unresolved secret reference: %s", ref)
+	}
+
 	return dotGalaxy
 }
 
@@ -100,6 +120,8 @@ func init() {
 
 	flags.String("environment", "", "target environments, comma separated list")
 	flags.String("namespace", "", "target namespaces, comma separated list")
+	flags.Int("max-concurrency", 1, "maximum number of environments applied in parallel")
+	flags.Bool("skip-history", false, "skip storing a release history snapshot after apply")
 
 	if err := viper.BindPFlags(flags); err != nil {
 		log.Fatal(err)
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: `Inspect release history stored in-cluster.`,
+}
+
+var historyLsCmd = &cobra.Command{
+	Use:   "ls <namespace>",
+	Short: `List stored release revisions for a namespace.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := configFromEnv()
+		galaxy.SetLogLevel(cfg.LogLevel)
+
+		h, err := galaxy.NewHistory(cfg.KubernetesConfig)
+		if err != nil {
+			log.Fatalf("[ERROR] Building history client: %s", err)
+		}
+
+		entries, err := h.List(args[0])
+		if err != nil {
+			log.Fatalf("[ERROR] Listing history: %s", err)
+		}
+
+		for _, entry := range entries {
+			fmt.Printf("revision %d\tenvironment=%s\ttimestamp=%s\tgitSha=%s\n",
+				entry.Revision, entry.Environment, entry.Timestamp, entry.GitSHA)
+		}
+	},
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <namespace>",
+	Short: `Rollback a namespace to a previously stored revision.`,
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := configFromEnv()
+		galaxy.SetLogLevel(cfg.LogLevel)
+
+		ns := args[0]
+		revision := viper.GetInt("to")
+
+		h, err := galaxy.NewHistory(cfg.KubernetesConfig)
+		if err != nil {
+			log.Fatalf("[ERROR] Building history client: %s", err)
+		}
+
+		ctx, err := h.Rollback(ns, revision)
+		if err != nil {
+			log.Fatalf("[ERROR] Rolling back: %s", err)
+		}
+
+		envs := cfg.GetEnvironments()
+		if len(envs) != 1 {
+			log.Fatal("[ERROR] A single --environment must be informed to rollback")
+		}
+		envName := envs[0]
+
+		dotGalaxy := bootstrap(cfg)
+		env, err := dotGalaxy.GetEnvironment(envName)
+		if err != nil {
+			log.Fatalf("[ERROR] Loading environment '%s': %s", envName, err)
+		}
+
+		l := galaxy.NewLandscaper(cfg.LandscaperConfig, cfg.KubernetesConfig, env, []*galaxy.Context{ctx})
+		if err = l.Bootstrap(ns, ns, cfg.DryRun); err != nil {
+			log.Fatalf("[ERROR] Bootstrapping rollback: %s", err)
+		}
+		if err = l.Apply(); err != nil {
+			log.Fatalf("[ERROR] Applying rollback: %s", err)
+		}
+
+		fmt.Printf("Rolled back namespace '%s' to revision %d\n", ns, revision)
+	},
+}
+
+func init() {
+	rollbackCmd.Flags().Int("to", 0, "revision to rollback to")
+	if err := viper.BindPFlag("to", rollbackCmd.Flags().Lookup("to")); err != nil {
+		log.Fatal(err)
+	}
+
+	historyCmd.AddCommand(historyLsCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(rollbackCmd)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/otaviof/galaxy/pkg/galaxy"
+)
+
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: `Print the resolved, merged workspace specification.`,
+	Long: `# galaxy workspace
+
+Discover the current workspace by walking up from the working directory looking for a
+'.galaxy.yaml' file, merge any '.galaxy.d/*.yaml' overlay found next to it, and print the
+resulting specification.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg := configFromEnv()
+		galaxy.SetLogLevel(cfg.LogLevel)
+
+		workspace, err := galaxy.DiscoverWorkspace(cfg.DotGalaxyPath)
+		if err != nil {
+			log.Fatalf("[ERROR] Discovering workspace: %s", err)
+		}
+
+		out, err := yaml.Marshal(workspace.DotGalaxy)
+		if err != nil {
+			log.Fatalf("[ERROR] Rendering workspace: %s", err)
+		}
+
+		fmt.Printf("# workspace root: %s\n%s", workspace.RootDir, out)
+
+		fmt.Println("# provenance:")
+		for _, ep := range workspace.Environments() {
+			fmt.Printf("#   environment %-20s %s\n", ep.Environment.Name, ep.Source)
+		}
+		for _, np := range workspace.Namespaces() {
+			fmt.Printf("#   namespace    %-20s %s\n", np.Name, np.Source)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+}
@@ -20,10 +20,25 @@ type Spec struct {
 
 // Environment representation, related to environment scope and transformation
 type Environment struct {
-	Name             string    `yaml:"name"`
-	SkipOnNamespaces []string  `yaml:"skipOnNamespaces"`
-	FileSuffixes     []string  `yaml:"fileSuffixes"`
-	Transform        Transform `yaml:"transform"`
+	Name             string        `yaml:"name"`
+	SkipOnNamespaces []string      `yaml:"skipOnNamespaces"`
+	FileSuffixes     []string      `yaml:"fileSuffixes"`
+	Transform        Transform     `yaml:"transform"`
+	Secrets          SecretsConfig `yaml:"secrets"`
+}
+
+// SecretsConfig selects which SecretsProvider implementation an environment uses.
+type SecretsConfig struct {
+	Provider string `yaml:"provider"`
+}
+
+// SecretsProviderName returns the configured SecretsProvider name, defaulting to
+// SecretsProviderVaultHandler to preserve the pre-existing behavior.
+func (e *Environment) SecretsProviderName() string {
+	if e.Secrets.Provider == "" {
+		return SecretsProviderVaultHandler
+	}
+	return e.Secrets.Provider
 }
 
 // Transform configuration on how to transform a release for that environment
@@ -2,18 +2,21 @@ package galaxy
 
 import (
 	"fmt"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 // Galaxy holds application runtime items
 type Galaxy struct {
-	logger        *log.Entry                   // logger
-	dotGalaxy     *DotGalaxy                   // global configuration
-	cfg           *Config                      // runtime configuration
-	original      Data                         // original contexts per env
-	Modified      Data                         // modified contexts per env
-	envOriginalNs map[string]map[string]string // mapping original namespace names per env
+	logger         *log.Entry                                    // logger
+	dotGalaxy      *DotGalaxy                                    // global configuration
+	cfg            *Config                                       // runtime configuration
+	original       Data                                          // original contexts per env
+	Modified       Data                                          // modified contexts per env
+	envOriginalNs  map[string]map[string]string                  // mapping original namespace names per env
+	resourceScopes map[string]map[schema.GroupVersionKind]Scope  // CRD scope per namespace, learned during Loop
 }
 
 // Data belonging to Galaxy, having environment name as key and a list of contexts
@@ -52,6 +55,10 @@ func (g *Galaxy) Plan() error {
 			return err
 		}
 
+		if env.Transform.NamespaceSuffix != "" {
+			g.warnClusterScopedRenames(logger, env)
+		}
+
 		logger.Info("Planing...")
 		plan := NewPlan(env, g.cfg.GetNamespaces(), ctx)
 		if modified, err = plan.ContextForEnvironment(); err != nil {
@@ -66,19 +73,77 @@ func (g *Galaxy) Plan() error {
 	})
 }
 
-// Apply changes planned just before.
+// warnClusterScopedRenames logs a warning for every cluster-scoped custom resource kind found by
+// ScanResourceScopes while env applies a NamespaceSuffix: cluster-scoped resources live outside
+// any namespace, so renaming the namespace they ship alongside has no effect on them and can hide
+// a naming collision between environments.
+func (g *Galaxy) warnClusterScopedRenames(logger *log.Entry, env *Environment) {
+	for ns, scopes := range g.resourceScopes {
+		for gvk, scope := range scopes {
+			if scope == ScopeCluster {
+				logger.Warnf("Namespace '%s' declares cluster-scoped resource kind '%s' ('%s'); "+
+					"Transform.NamespaceSuffix '%s' has no effect on cluster-scoped resources",
+					ns, gvk.Kind, gvk.GroupVersion().String(), env.Transform.NamespaceSuffix)
+			}
+		}
+	}
+}
+
+// Apply changes planned just before, targeting every informed environment. Environments are
+// applied concurrently, bounded by Config.MaxConcurrency, and errors are aggregated so a failure
+// on one environment doesn't prevent the others from being promoted.
 func (g *Galaxy) Apply() error {
-	var e *Environment
-	var envName string
-	var v *VaultHandler
+	var envs []string
 	var err error
 
 	g.logger.Infof("DRY-RUN: '%v', Environment: '%s'", g.cfg.DryRun, g.cfg.GetEnvironments())
 
-	if envName, err = g.probeSingleEnv(); err != nil {
+	if envs, err = g.probeEnvs(); err != nil {
 		return err
 	}
 
+	maxConcurrency := g.cfg.MaxConcurrency
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
+	results := make([]error, len(envs))
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+
+	for i, envName := range envs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, envName string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = g.applyEnv(envName)
+		}(i, envName)
+	}
+	wg.Wait()
+
+	errs := &MultiError{}
+	g.logger.Info("Apply summary:")
+	for i, envName := range envs {
+		if results[i] != nil {
+			g.logger.Errorf("  - %s: FAILED (%s)", envName, results[i])
+			errs.Add(envName, results[i])
+		} else {
+			g.logger.Infof("  - %s: OK", envName)
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+// applyEnv applies the previously planned changes for a single environment.
+func (g *Galaxy) applyEnv(envName string) error {
+	var e *Environment
+	var err error
+
 	logger := g.logger.WithFields(log.Fields{"env": envName, "dryRun": g.cfg.DryRun})
 	logger.Infof("Applying changes for environment...")
 
@@ -86,18 +151,23 @@ func (g *Galaxy) Apply() error {
 		return err
 	}
 
+	l := NewLandscaper(g.cfg.LandscaperConfig, g.cfg.KubernetesConfig, e, g.Modified[envName])
+
+	var provider SecretsProvider
 	if !g.cfg.SkipSecrets {
-		v = NewVaultHandler(g.cfg.VaultHandlerConfig, g.cfg.KubernetesConfig, g.Modified[envName])
+		var providerErr error
+		if provider, providerErr = g.secretsProviderFor(e, envName); providerErr != nil {
+			return providerErr
+		}
 	}
 
-	l := NewLandscaper(g.cfg.LandscaperConfig, g.cfg.KubernetesConfig, e, g.Modified[envName])
 	for ns, originalNs := range g.envOriginalNs[envName] {
 		if !g.cfg.SkipSecrets {
-			logger.Infof("Handling secrets for '%s' namespace", ns)
-			if err = v.Bootstrap(ns, g.cfg.DryRun); err != nil {
+			logger.Infof("Handling secrets for '%s' namespace via '%s' provider", ns, provider.Name())
+			if err = provider.Bootstrap(ns, g.cfg.DryRun); err != nil {
 				return err
 			}
-			if err = v.Apply(); err != nil {
+			if err = provider.Apply(); err != nil {
 				return err
 			}
 		}
@@ -109,19 +179,63 @@ func (g *Galaxy) Apply() error {
 		if err = l.Apply(); err != nil {
 			return err
 		}
+
+		if !g.cfg.DryRun && !g.cfg.SkipHistory {
+			if histErr := g.snapshotHistory(envName, ns); histErr != nil {
+				logger.Warnf("Could not store history snapshot for namespace '%s': %s", ns, histErr)
+			}
+		}
 	}
 	return nil
 }
 
+// snapshotHistory stores envName's currently planned Context as a new history revision under
+// namespace ns, so a bad promotion can later be rolled back without needing the original
+// filesystem tree.
+func (g *Galaxy) snapshotHistory(envName string, ns string) error {
+	contexts := g.Modified[envName]
+	if len(contexts) == 0 {
+		return nil
+	}
+
+	h, err := NewHistory(g.cfg.KubernetesConfig)
+	if err != nil {
+		return err
+	}
+	return h.Snapshot(envName, ns, contexts[0])
+}
+
+// secretsProviderFor selects the SecretsProvider configured for env, defaulting to the
+// pre-existing VaultHandler behavior. It is built once per environment (same as NewLandscaper
+// just above its call site), with per-namespace resolution, such as locating a namespace
+// directory, deferred to Bootstrap(ns, dryRun), so it doesn't re-authenticate to Vault on every
+// namespace.
+func (g *Galaxy) secretsProviderFor(env *Environment, envName string) (SecretsProvider, error) {
+	switch env.SecretsProviderName() {
+	case SecretsProviderSealedSecrets:
+		return NewSealedSecretsProvider(g.cfg.KubernetesConfig, g.dotGalaxy), nil
+	case SecretsProviderVaultHandler:
+		v := NewVaultHandler(g.cfg.VaultHandlerConfig, g.cfg.KubernetesConfig, g.Modified[envName])
+		return vaultHandlerAdapter{v}, nil
+	default:
+		return nil, fmt.Errorf("unknown secrets provider '%s' for environment '%s'", env.SecretsProviderName(), envName)
+	}
+}
+
 // Loop over environments and its contexts.
 func (g *Galaxy) Loop(fn actOnContext) error {
 	var exts = g.dotGalaxy.Spec.Namespaces.Extensions
 	var err error
 
 	logger := g.logger.WithField("exts", exts)
-	for _, env := range g.dotGalaxy.ListEnvironments() {
+	for _, envName := range g.dotGalaxy.ListEnvironments() {
 		ctx := NewContext()
-		logger = g.logger.WithField("env", env)
+		logger = g.logger.WithField("env", envName)
+
+		env, envErr := g.dotGalaxy.GetEnvironment(envName)
+		if envErr != nil {
+			return envErr
+		}
 
 		for _, ns := range g.dotGalaxy.ListNamespaces() {
 			var baseDir string
@@ -129,6 +243,24 @@ func (g *Galaxy) Loop(fn actOnContext) error {
 			if baseDir, err = g.dotGalaxy.GetNamespaceDir(ns); err != nil {
 				return err
 			}
+
+			var cleanupResolved func()
+			if baseDir, cleanupResolved, err = ResolveFileSuffixes(env, baseDir, g.cfg.VaultHandlerConfig, g.cfg.KubernetesConfig, g.cfg.DryRun); err != nil {
+				return err
+			}
+			defer cleanupResolved()
+
+			scopes, err := ScanResourceScopes(baseDir)
+			if err != nil {
+				return err
+			}
+			if g.resourceScopes[ns] == nil {
+				g.resourceScopes[ns] = make(map[schema.GroupVersionKind]Scope)
+			}
+			for gvk, scope := range scopes {
+				g.resourceScopes[ns][gvk] = scope
+			}
+
 			logger.Infof("Inspecting namespace '%s', directory '%s'", ns, baseDir)
 			if err = ctx.InspectDir(ns, baseDir, exts); err != nil {
 				logger.Fatalf("error during inspecting context: %#v", err)
@@ -136,42 +268,62 @@ func (g *Galaxy) Loop(fn actOnContext) error {
 			}
 		}
 
-		if err = fn(logger, env, ctx); err != nil {
+		if err = fn(logger, envName, ctx); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// probeSingleEnv make sure a single environment is informed, and it's present in planned data, also
-// original name is able to be found.
-func (g *Galaxy) probeSingleEnv() (string, error) {
-	if len(g.cfg.GetEnvironments()) != 1 {
-		return "", fmt.Errorf("a single environment must be informed")
-	}
-
-	envName := g.cfg.GetEnvironments()[0]
-
-	g.logger.Info("Checking if environment is listed at planned data...")
+// probeEnv makes sure envName is present in planned data, and its original namespace names are
+// able to be found.
+func (g *Galaxy) probeEnv(envName string) error {
+	g.logger.Infof("Checking if environment '%s' is listed at planned data...", envName)
 	if _, found := g.Modified[envName]; !found {
-		return "", fmt.Errorf("environment '%s' is not found on planned data", envName)
+		return fmt.Errorf("environment '%s' is not found on planned data", envName)
 	}
 	g.logger.Debug("Retrieving original namespace name...")
 	if _, found := g.envOriginalNs[envName]; !found {
-		return "", fmt.Errorf("environment '%s' is not found on original namespace names map", envName)
+		return fmt.Errorf("environment '%s' is not found on original namespace names map", envName)
 	}
+	return nil
+}
 
-	return envName, nil
+// probeEnvs returns the list of target environments informed via configuration, or every
+// environment present on planned data when none is informed, making sure each one is ready to
+// be applied.
+func (g *Galaxy) probeEnvs() ([]string, error) {
+	envs := g.cfg.GetEnvironments()
+	if len(envs) == 0 {
+		// iterate ListEnvironments(), not g.Modified directly, so apply order is stable across
+		// runs instead of following Go's randomized map iteration order.
+		for _, envName := range g.dotGalaxy.ListEnvironments() {
+			if _, found := g.Modified[envName]; found {
+				envs = append(envs, envName)
+			}
+		}
+	}
+	if len(envs) == 0 {
+		return nil, fmt.Errorf("no environment informed, and none found on planned data")
+	}
+
+	for _, envName := range envs {
+		if err := g.probeEnv(envName); err != nil {
+			return nil, err
+		}
+	}
+	return envs, nil
 }
 
 // NewGalaxy instantiages a new application instance.
 func NewGalaxy(dotGalaxy *DotGalaxy, cfg *Config) *Galaxy {
 	return &Galaxy{
-		logger:        log.WithFields(log.Fields{"type": "galaxy", "dryRun": cfg.DryRun}),
-		dotGalaxy:     dotGalaxy,
-		cfg:           cfg,
-		original:      make(Data),
-		Modified:      make(Data),
-		envOriginalNs: make(map[string]map[string]string),
+		logger:         log.WithFields(log.Fields{"type": "galaxy", "dryRun": cfg.DryRun}),
+		dotGalaxy:      dotGalaxy,
+		cfg:            cfg,
+		original:       make(Data),
+		Modified:       make(Data),
+		envOriginalNs:  make(map[string]map[string]string),
+		resourceScopes: make(map[string]map[schema.GroupVersionKind]Scope),
 	}
 }
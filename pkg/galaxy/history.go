@@ -0,0 +1,143 @@
+package galaxy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	historyManagedByLabel = "app.kubernetes.io/managed-by"
+	historyManagedByValue = "galaxy"
+	historySecretPrefix   = "galaxy-history-"
+)
+
+// HistoryEntry is a single revision of a namespace's planned Context, stored in-cluster as a
+// Secret, the same way Helm v3 keeps its own release state alongside the release it describes.
+type HistoryEntry struct {
+	Environment string          `json:"environment"`
+	Namespace   string          `json:"namespace"`
+	Revision    int             `json:"revision"`
+	Timestamp   time.Time       `json:"timestamp"`
+	GitSHA      string          `json:"gitSha,omitempty"`
+	Context     json.RawMessage `json:"context"`
+}
+
+// History reads and writes HistoryEntry snapshots as Kubernetes Secrets, labeled
+// "app.kubernetes.io/managed-by=galaxy", in the same namespace as the release they describe.
+type History struct {
+	logger    *log.Entry
+	clientset kubernetes.Interface
+}
+
+// NewHistory builds a History talking to the cluster described by cfg.
+func NewHistory(cfg *KubernetesConfig) (*History, error) {
+	clientset, err := kubernetesClientset(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &History{logger: log.WithField("type", "history"), clientset: clientset}, nil
+}
+
+// Snapshot stores ctx's planned state for namespace ns as the next revision.
+func (h *History) Snapshot(envName string, ns string, ctx *Context) error {
+	entries, err := h.List(ns)
+	if err != nil {
+		return err
+	}
+
+	contextJSON, err := json.Marshal(ctx)
+	if err != nil {
+		return err
+	}
+
+	entry := HistoryEntry{
+		Environment: envName,
+		Namespace:   ns,
+		Revision:    len(entries) + 1,
+		Timestamp:   time.Now(),
+		GitSHA:      gitSHA(),
+		Context:     contextJSON,
+	}
+
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s%d", historySecretPrefix, entry.Revision),
+			Namespace: ns,
+			Labels:    map[string]string{historyManagedByLabel: historyManagedByValue},
+		},
+		Data: map[string][]byte{"entry": payload},
+	}
+
+	h.logger.Infof("Storing history revision %d for namespace '%s'", entry.Revision, ns)
+	_, err = h.clientset.CoreV1().Secrets(ns).Create(secret)
+	return err
+}
+
+// List returns every stored revision for namespace ns, ordered oldest to newest.
+func (h *History) List(ns string) ([]HistoryEntry, error) {
+	selector := fmt.Sprintf("%s=%s", historyManagedByLabel, historyManagedByValue)
+	secrets, err := h.clientset.CoreV1().Secrets(ns).List(metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var entries []HistoryEntry
+	for _, secret := range secrets.Items {
+		var entry HistoryEntry
+		if err := json.Unmarshal(secret.Data["entry"], &entry); err != nil {
+			return nil, fmt.Errorf("decoding history secret '%s': %s", secret.Name, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Revision < entries[j].Revision })
+	return entries, nil
+}
+
+// Rollback reconstructs the Context stored at revision for namespace ns, so it can be fed back
+// through Landscaper.Bootstrap/Apply without needing the original filesystem tree.
+func (h *History) Rollback(ns string, revision int) (*Context, error) {
+	entries, err := h.List(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Revision == revision {
+			ctx := NewContext()
+			if err := json.Unmarshal(entry.Context, ctx); err != nil {
+				return nil, fmt.Errorf("decoding revision %d for namespace '%s': %s", revision, ns, err)
+			}
+			return ctx, nil
+		}
+	}
+
+	return nil, fmt.Errorf("revision %d not found for namespace '%s'", revision, ns)
+}
+
+// gitSHA returns the current git commit SHA, or an empty string when it can't be determined.
+func gitSHA() string {
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
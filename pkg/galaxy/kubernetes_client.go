@@ -0,0 +1,47 @@
+package galaxy
+
+import (
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// kubernetesRESTConfig builds a *rest.Config from cfg, honoring in-cluster, kubeconfig and context
+// settings. Shared by every client builder so they all resolve a cluster the same way.
+func kubernetesRESTConfig(cfg *KubernetesConfig) (*rest.Config, error) {
+	if cfg.InCluster {
+		return rest.InClusterConfig()
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = cfg.KubeConfig
+
+	overrides := &clientcmd.ConfigOverrides{}
+	if cfg.KubeContext != "" {
+		overrides.CurrentContext = cfg.KubeContext
+	}
+
+	clientCfg := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides)
+	return clientCfg.ClientConfig()
+}
+
+// kubernetesClientset builds a client-go clientset from cfg, the same way Landscaper and
+// VaultHandler already talk to the cluster.
+func kubernetesClientset(cfg *KubernetesConfig) (kubernetes.Interface, error) {
+	restCfg, err := kubernetesRESTConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(restCfg)
+}
+
+// kubernetesDynamicClient builds a client-go dynamic client from cfg, used to apply arbitrary
+// CRD-typed manifests (e.g. SealedSecret) without depending on their generated typed clientset.
+func kubernetesDynamicClient(cfg *KubernetesConfig) (dynamic.Interface, error) {
+	restCfg, err := kubernetesRESTConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return dynamic.NewForConfig(restCfg)
+}
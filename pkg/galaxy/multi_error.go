@@ -0,0 +1,42 @@
+package galaxy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MultiError aggregates the errors produced while acting on more than one environment at once,
+// so a failure applying one environment doesn't hide failures on the others.
+type MultiError struct {
+	envErrors map[string]error
+}
+
+// Add registers an error for a given environment.
+func (m *MultiError) Add(envName string, err error) {
+	if m.envErrors == nil {
+		m.envErrors = make(map[string]error)
+	}
+	m.envErrors[envName] = err
+}
+
+// HasErrors informs whether at least one environment has failed.
+func (m *MultiError) HasErrors() bool {
+	return len(m.envErrors) > 0
+}
+
+// Error renders every collected environment error as a single message, sorted by environment
+// name so the summary is stable across runs.
+func (m *MultiError) Error() string {
+	names := make([]string, 0, len(m.envErrors))
+	for envName := range m.envErrors {
+		names = append(names, envName)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, envName := range names {
+		lines = append(lines, fmt.Sprintf("%s: %s", envName, m.envErrors[envName]))
+	}
+	return fmt.Sprintf("errors applying %d environment(s): %s", len(m.envErrors), strings.Join(lines, "; "))
+}
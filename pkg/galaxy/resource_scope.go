@@ -0,0 +1,115 @@
+package galaxy
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Scope describes whether a Kubernetes resource kind is namespaced or cluster-wide.
+type Scope string
+
+const (
+	// ScopeNamespaced marks a kind as living inside a namespace.
+	ScopeNamespaced Scope = "Namespaced"
+	// ScopeCluster marks a kind as living outside any namespace.
+	ScopeCluster Scope = "Cluster"
+)
+
+// crdManifest is the minimal shape needed to read scope information out of a
+// CustomResourceDefinition manifest, without depending on the full apiextensions types.
+type crdManifest struct {
+	Kind string `yaml:"kind"`
+	Spec struct {
+		Group string `yaml:"group"`
+		Scope string `yaml:"scope"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		Version  string `yaml:"version"`
+		Versions []struct {
+			Name string `yaml:"name"`
+		} `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+// ScanResourceScopes walks every `*.yaml` file directly under dir, looking for
+// CustomResourceDefinition manifests, and indexes the scope each one declares for its kind. This
+// lets scope be learned directly from the release tree (modelled on how Flux falls back to
+// reading CRDs out of the manifests it is applying), instead of requiring the CRD to already be
+// installed in the cluster. A file bundling several manifests separated by `---` is fully
+// decoded, document by document, so CRDs after the first one are not skipped.
+//
+// Galaxy.Loop calls ScanResourceScopes per namespace directory and merges the results into
+// Galaxy.resourceScopes, which Plan consults to flag cluster-scoped CRs affected by
+// Transform.NamespaceSuffix.
+func ScanResourceScopes(dir string) (map[schema.GroupVersionKind]Scope, error) {
+	scopes := make(map[schema.GroupVersionKind]Scope)
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range matches {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		decoder := yaml.NewDecoder(bytes.NewReader(contents))
+		for {
+			var crd crdManifest
+			if err := decoder.Decode(&crd); err != nil {
+				if err == io.EOF {
+					break
+				}
+				break
+			}
+			if crd.Kind != "CustomResourceDefinition" {
+				continue
+			}
+
+			for _, gvk := range crdGroupVersionKinds(crd) {
+				scopes[gvk] = crdScope(crd.Spec.Scope)
+			}
+		}
+	}
+
+	return scopes, nil
+}
+
+// crdGroupVersionKinds expands a CRD manifest into one GroupVersionKind per declared version,
+// supporting both the legacy single `spec.version` and the newer `spec.versions` list.
+func crdGroupVersionKinds(crd crdManifest) []schema.GroupVersionKind {
+	var versions []string
+	if crd.Spec.Version != "" {
+		versions = append(versions, crd.Spec.Version)
+	}
+	for _, v := range crd.Spec.Versions {
+		versions = append(versions, v.Name)
+	}
+
+	var gvks []schema.GroupVersionKind
+	for _, version := range versions {
+		gvks = append(gvks, schema.GroupVersionKind{
+			Group:   crd.Spec.Group,
+			Version: version,
+			Kind:    crd.Spec.Names.Kind,
+		})
+	}
+	return gvks
+}
+
+// crdScope normalizes a raw `spec.scope` value, defaulting to Namespaced as Kubernetes itself
+// does.
+func crdScope(scope string) Scope {
+	if scope == string(ScopeCluster) {
+		return ScopeCluster
+	}
+	return ScopeNamespaced
+}
@@ -0,0 +1,309 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	vaultRefPattern  = regexp.MustCompile(`{{\s*vault:([^#}]+)#([^}\s]+)\s*}}`)
+	secretRefPattern = regexp.MustCompile(`{{\s*secret:([^/}]+)/([^:}]+):([^}\s]+)\s*}}`)
+)
+
+// SecretResolver substitutes `{{ vault:<path>#<field> }}` and `{{ secret:<namespace>/<name>:<key> }}`
+// references found in configuration values with the actual secret material, resolving against the
+// already configured Vault and Kubernetes clients.
+type SecretResolver struct {
+	logger     *log.Entry
+	vaultCfg   *VaultHandlerConfig
+	clientset  kubernetes.Interface
+	dryRun     bool
+	unresolved []string
+}
+
+// NewSecretResolver builds a SecretResolver. When dryRun is true, references that fail to resolve
+// are left as placeholders and collected in Unresolved() instead of failing.
+func NewSecretResolver(vaultCfg *VaultHandlerConfig, k8sCfg *KubernetesConfig, dryRun bool) (*SecretResolver, error) {
+	clientset, err := kubernetesClientset(k8sCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretResolver{
+		logger:    log.WithField("type", "secretResolver"),
+		vaultCfg:  vaultCfg,
+		clientset: clientset,
+		dryRun:    dryRun,
+	}, nil
+}
+
+// Unresolved returns every reference that could not be resolved, populated only in dry-run mode.
+func (r *SecretResolver) Unresolved() []string {
+	return r.unresolved
+}
+
+// ResolveString substitutes every recognized reference found in s.
+func (r *SecretResolver) ResolveString(s string) (string, error) {
+	var outerErr error
+
+	s = vaultRefPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := vaultRefPattern.FindStringSubmatch(match)
+		value, err := r.resolveVault(groups[1], groups[2])
+		return r.handleResolved(match, value, err, &outerErr)
+	})
+	if outerErr != nil {
+		return "", outerErr
+	}
+
+	s = secretRefPattern.ReplaceAllStringFunc(s, func(match string) string {
+		groups := secretRefPattern.FindStringSubmatch(match)
+		value, err := r.resolveSecret(groups[1], groups[2], groups[3])
+		return r.handleResolved(match, value, err, &outerErr)
+	})
+	if outerErr != nil {
+		return "", outerErr
+	}
+
+	return s, nil
+}
+
+// handleResolved reconciles a single substitution attempt with the resolver's dry-run policy.
+func (r *SecretResolver) handleResolved(match string, value string, err error, outerErr *error) string {
+	if err == nil {
+		return value
+	}
+	if r.dryRun {
+		r.logger.Warnf("Leaving unresolved reference '%s' in place: %s", match, err)
+		r.unresolved = append(r.unresolved, match)
+		return match
+	}
+	if *outerErr == nil {
+		*outerErr = err
+	}
+	return match
+}
+
+// resolveVault reads field from the Vault secret stored at path.
+func (r *SecretResolver) resolveVault(path string, field string) (string, error) {
+	if r.vaultCfg == nil {
+		return "", fmt.Errorf("vault is not configured, cannot resolve '%s#%s'", path, field)
+	}
+
+	data, err := readVaultSecret(r.vaultCfg, path)
+	if err != nil {
+		return "", err
+	}
+
+	value, found := data[field]
+	if !found {
+		return "", fmt.Errorf("field '%s' not found at vault path '%s'", field, path)
+	}
+	return value, nil
+}
+
+// resolveSecret reads key from the Kubernetes Secret namespace/name.
+func (r *SecretResolver) resolveSecret(namespace string, name string, key string) (string, error) {
+	secret, err := r.clientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	value, found := secret.Data[key]
+	if !found {
+		return "", fmt.Errorf("key '%s' not found on secret '%s/%s'", key, namespace, name)
+	}
+	return string(value), nil
+}
+
+// ResolveTransform substitutes references found on an Environment's Transform fields, in place.
+func (r *SecretResolver) ResolveTransform(t *Transform) error {
+	resolved, err := r.ResolveString(t.NamespaceSuffix)
+	if err != nil {
+		return err
+	}
+	t.NamespaceSuffix = resolved
+
+	if resolved, err = r.ResolveString(t.ReleasePrefix); err != nil {
+		return err
+	}
+	t.ReleasePrefix = resolved
+
+	return nil
+}
+
+// ResolveDotGalaxy walks every environment's Transform in dotGalaxy, substituting secret
+// references in place. This is meant to run after NewDotGalaxy and before Plan/Apply. Release
+// value files discovered via Environment.FileSuffixes are resolved separately, per namespace
+// directory, by ResolveFileSuffixes.
+func ResolveDotGalaxy(dotGalaxy *DotGalaxy, vaultCfg *VaultHandlerConfig, k8sCfg *KubernetesConfig, dryRun bool) (*SecretResolver, error) {
+	resolver, err := NewSecretResolver(vaultCfg, k8sCfg, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range dotGalaxy.Spec.Environments {
+		if err := resolver.ResolveTransform(&dotGalaxy.Spec.Environments[i].Transform); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolver, nil
+}
+
+// ResolveFileSuffixes substitutes vault/secret references found in every release value file under
+// baseDir whose name ends with one of env.FileSuffixes. Matching files are rewritten into a fresh
+// copy of baseDir (so on-disk override files are never mutated in place and stay placeholder-only
+// in the repository), and the directory InspectDir should read from is returned. When no file
+// under baseDir contains a reference, baseDir itself is returned untouched and no Vault/Kubernetes
+// client is ever built, so environments that don't use this feature pay no extra cost. The
+// returned cleanup func removes the mirrored copy, if one was created, and must be called once the
+// caller is done reading from the returned directory (e.g. via `defer`).
+func ResolveFileSuffixes(env *Environment, baseDir string, vaultCfg *VaultHandlerConfig, k8sCfg *KubernetesConfig, dryRun bool) (string, func(), error) {
+	noopCleanup := func() {}
+
+	if len(env.FileSuffixes) == 0 {
+		return baseDir, noopCleanup, nil
+	}
+
+	var matches []string
+	for _, suffix := range env.FileSuffixes {
+		found, err := filepath.Glob(filepath.Join(baseDir, "*"+suffix))
+		if err != nil {
+			return "", noopCleanup, err
+		}
+		matches = append(matches, found...)
+	}
+
+	var resolver *SecretResolver
+	resolvedDir := baseDir
+	cleanup := noopCleanup
+
+	for _, file := range matches {
+		contents, err := ioutil.ReadFile(file)
+		if err != nil {
+			return "", cleanup, err
+		}
+		if !vaultRefPattern.Match(contents) && !secretRefPattern.Match(contents) {
+			continue
+		}
+
+		if resolver == nil {
+			if resolver, err = NewSecretResolver(vaultCfg, k8sCfg, dryRun); err != nil {
+				return "", cleanup, err
+			}
+			if resolvedDir, err = mirrorDir(baseDir); err != nil {
+				return "", cleanup, err
+			}
+			cleanup = func() {
+				if err := os.RemoveAll(resolvedDir); err != nil {
+					log.Warnf("Could not remove resolved value file mirror '%s': %s", resolvedDir, err)
+				}
+			}
+		}
+
+		resolved, err := resolver.ResolveString(string(contents))
+		if err != nil {
+			return "", cleanup, err
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(resolvedDir, filepath.Base(file)), []byte(resolved), 0600); err != nil {
+			return "", cleanup, err
+		}
+	}
+
+	return resolvedDir, cleanup, nil
+}
+
+// mirrorDir recursively copies every file and subdirectory under src into a new temporary
+// directory and returns its path, so the mirrored copy looks exactly like src to InspectDir.
+func mirrorDir(src string) (string, error) {
+	dst, err := ioutil.TempDir("", "galaxy-resolved-")
+	if err != nil {
+		return "", err
+	}
+
+	err = filepath.Walk(src, func(path string, info os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, contents, info.Mode())
+	})
+	if err != nil {
+		return "", err
+	}
+	return dst, nil
+}
+
+// newVaultClient authenticates against Vault using a token when informed, falling back to
+// AppRole login with VaultRoleID/VaultSecretID.
+func newVaultClient(cfg *VaultHandlerConfig) (*vaultapi.Client, error) {
+	client, err := vaultapi.NewClient(&vaultapi.Config{Address: cfg.VaultAddr})
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.VaultToken != "" {
+		client.SetToken(cfg.VaultToken)
+		return client, nil
+	}
+
+	if cfg.VaultRoleID == "" || cfg.VaultSecretID == "" {
+		return nil, fmt.Errorf("no vault token, and no role-id/secret-id pair, informed")
+	}
+
+	loginSecret, err := client.Logical().Write("auth/approle/login", map[string]interface{}{
+		"role_id":   cfg.VaultRoleID,
+		"secret_id": cfg.VaultSecretID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client.SetToken(loginSecret.Auth.ClientToken)
+	return client, nil
+}
+
+// readVaultSecret reads every string field stored at path.
+func readVaultSecret(cfg *VaultHandlerConfig, path string) (map[string]string, error) {
+	client, err := newVaultClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := client.Logical().Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil {
+		return nil, fmt.Errorf("no secret found at vault path '%s'", path)
+	}
+
+	data := make(map[string]string)
+	for k, v := range secret.Data {
+		if s, ok := v.(string); ok {
+			data[k] = s
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,200 @@
+package galaxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	yaml "gopkg.in/yaml.v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// SecretsProviderVaultHandler is the default, pre-existing secrets provider.
+	SecretsProviderVaultHandler = "vault-handler"
+	// SecretsProviderSealedSecrets applies `*.sealedsecret.yaml` manifests found in the
+	// namespace directory, instead of talking to Vault.
+	SecretsProviderSealedSecrets = "sealed-secrets"
+)
+
+// sealedSecretGVR identifies the Bitnami SealedSecret custom resource applied by
+// SealedSecretsProvider via the dynamic client.
+var sealedSecretGVR = schema.GroupVersionResource{
+	Group:    "bitnami.com",
+	Version:  "v1alpha1",
+	Resource: "sealedsecrets",
+}
+
+// SecretsProvider abstracts the secrets pipeline run before a namespace's releases are applied,
+// so environments aren't hard-wired to Vault.
+type SecretsProvider interface {
+	// Bootstrap prepares namespace ns for the secrets pipeline, honoring dryRun.
+	Bootstrap(ns string, dryRun bool) error
+	// Apply runs the secrets pipeline.
+	Apply() error
+	// Name identifies the provider, used in logging and in `.galaxy.yaml`.
+	Name() string
+}
+
+// vaultHandlerAdapter adapts *VaultHandler to the SecretsProvider interface, until VaultHandler
+// itself grows a Name method.
+type vaultHandlerAdapter struct {
+	*VaultHandler
+}
+
+// Name identifies this provider.
+func (vaultHandlerAdapter) Name() string {
+	return SecretsProviderVaultHandler
+}
+
+// SealedSecretsProvider applies Bitnami SealedSecret manifests found in a namespace directory,
+// for teams that don't run Vault, reusing the existing KubernetesConfig via a dynamic client. One
+// instance is shared across every namespace of an environment; Bootstrap resolves each
+// namespace's own release directory, so the provider can be built once per environment instead of
+// once per namespace.
+type SealedSecretsProvider struct {
+	logger        *log.Entry
+	kubernetesCfg *KubernetesConfig
+	dotGalaxy     *DotGalaxy
+	ns            string
+	dryRun        bool
+	manifests     []string
+}
+
+// NewSealedSecretsProvider builds a SealedSecretsProvider resolving namespace directories via
+// dotGalaxy.
+func NewSealedSecretsProvider(kubernetesCfg *KubernetesConfig, dotGalaxy *DotGalaxy) *SealedSecretsProvider {
+	return &SealedSecretsProvider{
+		logger:        log.WithField("type", "sealedSecretsProvider"),
+		kubernetesCfg: kubernetesCfg,
+		dotGalaxy:     dotGalaxy,
+	}
+}
+
+// Name identifies this provider.
+func (s *SealedSecretsProvider) Name() string {
+	return SecretsProviderSealedSecrets
+}
+
+// Bootstrap discovers every `*.sealedsecret.yaml` manifest under ns's directory, honoring dryRun.
+// The resolved ns is later forced onto each manifest in applyManifest, so the target namespace
+// always follows the same promotion (e.g. Transform.NamespaceSuffix) as the rest of the
+// environment, regardless of what `metadata.namespace` the manifest itself declares.
+func (s *SealedSecretsProvider) Bootstrap(ns string, dryRun bool) error {
+	baseDir, err := s.dotGalaxy.GetNamespaceDir(ns)
+	if err != nil {
+		return err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(baseDir, "*.sealedsecret.yaml"))
+	if err != nil {
+		return err
+	}
+	s.logger.Infof("Found %d sealed-secret manifest(s) for namespace '%s'", len(matches), ns)
+	s.ns = ns
+	s.manifests = matches
+	s.dryRun = dryRun
+	return nil
+}
+
+// Apply applies every manifest discovered during Bootstrap via the dynamic client, skipping the
+// actual cluster call when dryRun is set.
+func (s *SealedSecretsProvider) Apply() error {
+	if s.dryRun {
+		for _, manifest := range s.manifests {
+			s.logger.Infof("[DRY-RUN] Would apply sealed-secret manifest '%s'", manifest)
+		}
+		return nil
+	}
+
+	client, err := kubernetesDynamicClient(s.kubernetesCfg)
+	if err != nil {
+		return err
+	}
+
+	for _, manifest := range s.manifests {
+		if err := s.applyManifest(client, manifest); err != nil {
+			return fmt.Errorf("applying '%s': %s", manifest, err)
+		}
+	}
+	return nil
+}
+
+// applyManifest decodes a single SealedSecret manifest and applies it via the dynamic client,
+// forcing it into s.ns (the namespace resolved by Bootstrap, already following the environment's
+// namespace transform) regardless of what `metadata.namespace` the manifest file itself declares.
+// A create that fails because the object already exists falls back to fetching the current
+// object and updating it with its resourceVersion carried forward; any other create failure is
+// returned as-is.
+func (s *SealedSecretsProvider) applyManifest(client dynamic.Interface, manifest string) error {
+	contents, err := ioutil.ReadFile(manifest)
+	if err != nil {
+		return err
+	}
+
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal(contents, &obj); err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{Object: convertMapStringInterface(obj)}
+	u.SetNamespace(s.ns)
+
+	s.logger.Infof("Applying sealed-secret manifest '%s' into namespace '%s'", manifest, s.ns)
+
+	resource := client.Resource(sealedSecretGVR).Namespace(s.ns)
+	if _, err := resource.Create(u, metav1.CreateOptions{}); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+
+		existing, getErr := resource.Get(u.GetName(), metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		u.SetResourceVersion(existing.GetResourceVersion())
+
+		if _, updateErr := resource.Update(u, metav1.UpdateOptions{}); updateErr != nil {
+			return updateErr
+		}
+	}
+	return nil
+}
+
+// convertMapStringInterface recursively normalizes a map decoded by yaml.v2 (which produces
+// map[interface{}]interface{}) into the map[string]interface{} shape unstructured.Unstructured
+// requires.
+func convertMapStringInterface(in interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	switch m := in.(type) {
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			out[fmt.Sprintf("%v", k)] = normalizeValue(v)
+		}
+	case map[string]interface{}:
+		for k, v := range m {
+			out[k] = normalizeValue(v)
+		}
+	}
+	return out
+}
+
+// normalizeValue recursively applies convertMapStringInterface to nested maps and slices.
+func normalizeValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[interface{}]interface{}, map[string]interface{}:
+		return convertMapStringInterface(value)
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			list[i] = normalizeValue(item)
+		}
+		return list
+	default:
+		return value
+	}
+}
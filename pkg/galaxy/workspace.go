@@ -0,0 +1,191 @@
+package galaxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	// DotGalaxyFileName is the default manifest name DiscoverWorkspace walks upward looking for.
+	DotGalaxyFileName = ".galaxy.yaml"
+	// overlayDirName holds per-team overlay manifests merged into the workspace's Spec.
+	overlayDirName = ".galaxy.d"
+)
+
+// Workspace is a Galaxy project root, discovered by walking up the directory tree looking for a
+// `.galaxy.yaml` file, together with every `.galaxy.d/*.yaml` overlay merged into it. It tracks
+// which file contributed each environment and namespace, so error messages can point back at the
+// team-owned file responsible for it.
+type Workspace struct {
+	RootDir   string
+	DotGalaxy *DotGalaxy
+	sources   map[string]string // "env:<name>" or "namespace:<name>" -> source file path
+}
+
+// EnvironmentProvenance pairs an environment with the file it was declared in.
+type EnvironmentProvenance struct {
+	Environment Environment
+	Source      string
+}
+
+// NamespaceProvenance pairs a namespace name with the file it was declared in.
+type NamespaceProvenance struct {
+	Name   string
+	Source string
+}
+
+// Environments lists every environment known to the workspace, together with its source file.
+func (w *Workspace) Environments() []EnvironmentProvenance {
+	var list []EnvironmentProvenance
+	for _, env := range w.DotGalaxy.Spec.Environments {
+		list = append(list, EnvironmentProvenance{
+			Environment: env,
+			Source:      w.sources["env:"+env.Name],
+		})
+	}
+	return list
+}
+
+// Namespaces lists every namespace name known to the workspace, together with its source file.
+func (w *Workspace) Namespaces() []NamespaceProvenance {
+	var list []NamespaceProvenance
+	for _, name := range w.DotGalaxy.Spec.Namespaces.Names {
+		list = append(list, NamespaceProvenance{
+			Name:   name,
+			Source: w.sources["namespace:"+name],
+		})
+	}
+	return list
+}
+
+// DiscoverWorkspace locates a workspace manifest: when manifestPath points at a file that exists
+// as given (e.g. an explicit `--config` path), that file is used directly; otherwise its base
+// name (defaulting to DotGalaxyFileName) is searched for by walking upward from the current
+// directory. Every `.galaxy.d/*.yaml` overlay found next to the resolved manifest is then merged
+// into the resulting Spec.
+func DiscoverWorkspace(manifestPath string) (*Workspace, error) {
+	rootDir, dotGalaxyPath, err := locateDotGalaxy(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	dotGalaxy, err := NewDotGalaxy(dotGalaxyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Workspace{RootDir: rootDir, DotGalaxy: dotGalaxy, sources: make(map[string]string)}
+	for _, env := range dotGalaxy.Spec.Environments {
+		w.sources["env:"+env.Name] = dotGalaxyPath
+	}
+	for _, name := range dotGalaxy.Spec.Namespaces.Names {
+		w.sources["namespace:"+name] = dotGalaxyPath
+	}
+
+	overlays, err := overlayFiles(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, overlayPath := range overlays {
+		if err := w.mergeOverlay(overlayPath); err != nil {
+			return nil, fmt.Errorf("merging overlay '%s': %s", overlayPath, err)
+		}
+	}
+
+	return w, nil
+}
+
+// locateDotGalaxy resolves manifestPath directly when it exists as given, otherwise walks upward
+// from the current directory looking for a file with the same base name (defaulting to
+// DotGalaxyFileName).
+func locateDotGalaxy(manifestPath string) (string, string, error) {
+	if manifestPath != "" {
+		if info, statErr := os.Stat(manifestPath); statErr == nil && !info.IsDir() {
+			abs, err := filepath.Abs(manifestPath)
+			if err != nil {
+				return "", "", err
+			}
+			return filepath.Dir(abs), abs, nil
+		}
+	}
+
+	fileName := filepath.Base(manifestPath)
+	if fileName == "" || fileName == "." {
+		fileName = DotGalaxyFileName
+	}
+	return findDotGalaxy(".", fileName)
+}
+
+// findDotGalaxy walks upward from startDir until it finds a file named fileName.
+func findDotGalaxy(startDir string, fileName string) (string, string, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return "", "", err
+	}
+
+	for {
+		candidate := filepath.Join(dir, fileName)
+		if info, statErr := os.Stat(candidate); statErr == nil && !info.IsDir() {
+			return dir, candidate, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", fmt.Errorf("no '%s' found walking up from '%s'", fileName, startDir)
+		}
+		dir = parent
+	}
+}
+
+// overlayFiles lists every `.galaxy.d/*.yaml` file found at the workspace root, sorted by name so
+// merges are deterministic.
+func overlayFiles(rootDir string) ([]string, error) {
+	overlayDir := filepath.Join(rootDir, overlayDirName)
+	if !isDir(overlayDir) {
+		return nil, nil
+	}
+
+	matches, err := filepath.Glob(filepath.Join(overlayDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// mergeOverlay loads a single overlay file and merges its environments and namespaces into the
+// workspace's Spec, recording provenance for each. An environment name already declared by the
+// base manifest or an earlier overlay is a conflict and is reported rather than silently
+// duplicated, pointing at both source files via provenance. A namespace name already declared
+// elsewhere is simply skipped, keeping its original provenance, since namespaces carry no
+// per-source data of their own to conflict over.
+func (w *Workspace) mergeOverlay(overlayPath string) error {
+	var overlay DotGalaxy
+
+	if err := yaml.Unmarshal(readFile(overlayPath), &overlay); err != nil {
+		return err
+	}
+
+	for _, env := range overlay.Spec.Environments {
+		if existingSource, found := w.sources["env:"+env.Name]; found {
+			return fmt.Errorf("environment '%s' is declared both in '%s' and '%s'",
+				env.Name, existingSource, overlayPath)
+		}
+		w.DotGalaxy.Spec.Environments = append(w.DotGalaxy.Spec.Environments, env)
+		w.sources["env:"+env.Name] = overlayPath
+	}
+
+	for _, name := range overlay.Spec.Namespaces.Names {
+		if stringSliceContains(w.DotGalaxy.Spec.Namespaces.Names, name) {
+			continue
+		}
+		w.DotGalaxy.Spec.Namespaces.Names = append(w.DotGalaxy.Spec.Namespaces.Names, name)
+		w.sources["namespace:"+name] = overlayPath
+	}
+
+	return nil
+}